@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// Result 单次压缩的结果
+type Result struct {
+	// 压缩后字节数
+	Size int64
+	// 压缩后宽度，提供方未返回时为 0
+	Width int
+	// 压缩后高度，提供方未返回时为 0
+	Height int
+	// Provider 实际完成压缩的提供方名称
+	Provider string
+}
+
+// Compressor 图片压缩提供方
+type Compressor interface {
+	// Name 提供方名称，用于日志与回退提示
+	Name() string
+	// Compress 压缩 srcPath 指向的图片，并将结果写入 dst
+	Compress(ctx context.Context, srcPath string, dst io.Writer) (Result, error)
+}
+
+// CompressionPipeline 按顺序尝试多个压缩提供方，前一个失败则自动回退到下一个
+type CompressionPipeline struct {
+	Providers []Compressor
+}
+
+// NewCompressionPipeline 按给定顺序注册压缩提供方
+func NewCompressionPipeline(providers ...Compressor) *CompressionPipeline {
+	return &CompressionPipeline{Providers: providers}
+}
+
+// Compress 依次尝试每个提供方，直到有一个成功；任一提供方的中间输出都不会污染 dst
+func (p *CompressionPipeline) Compress(ctx context.Context, srcPath string, dst io.Writer) (Result, error) {
+	if len(p.Providers) == 0 {
+		return Result{}, fmt.Errorf("未配置任何压缩提供方")
+	}
+
+	var lastErr error
+	for _, provider := range p.Providers {
+		cw := &countingWriter{}
+		result, err := provider.Compress(ctx, srcPath, cw)
+		if err != nil {
+			slog.Warn("压缩提供方失败，尝试下一个", "provider", provider.Name(), "err", err)
+			lastErr = err
+			continue
+		}
+		if _, err := dst.Write(cw.buf); err != nil {
+			return Result{}, err
+		}
+		result.Provider = provider.Name()
+		if result.Size == 0 {
+			result.Size = cw.count
+		}
+		return result, nil
+	}
+	return Result{}, fmt.Errorf("所有压缩提供方均失败：%w", lastErr)
+}
+
+// countingWriter 缓冲提供方的输出并统计字节数，只有提供方成功后才会转存到真正的目的地
+type countingWriter struct {
+	buf   []byte
+	count int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.buf = append(c.buf, p...)
+	c.count += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"strconv"
+)
+
+// ProviderConfig 各压缩提供方的鉴权信息，统一从环境变量加载，避免在代码中硬编码密钥
+type ProviderConfig struct {
+	// TinifyKeysFile keys.txt 风格的 Tinify Key 列表文件，每行一个 Key
+	TinifyKeysFile      string
+	ShortPixelAPIKey    string
+	CloudflareAccountID string
+	CloudflareAPIToken  string
+	// Concurrency 并发压缩的 worker 数量，对应 CompressionOptions.Concurrency
+	Concurrency int
+}
+
+// LoadProviderConfig 从环境变量加载各压缩提供方的鉴权信息以及压缩并发度
+func LoadProviderConfig() ProviderConfig {
+	return ProviderConfig{
+		TinifyKeysFile:      envOrDefault("TINIFY_KEYS_FILE", "keys.txt"),
+		ShortPixelAPIKey:    os.Getenv("SHORTPIXEL_API_KEY"),
+		CloudflareAccountID: os.Getenv("CLOUDFLARE_ACCOUNT_ID"),
+		CloudflareAPIToken:  os.Getenv("CLOUDFLARE_API_TOKEN"),
+		Concurrency:         envOrDefaultInt("IMAGE_CONCURRENCY", 4),
+	}
+}
+
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// LoadImageOptions 从环境变量加载压缩质量、MaxWidth/MaxHeight、是否保留原图/去除 EXIF
+// 以及自动上传到云存储的相关选项，与 LoadProviderConfig 的提供方鉴权信息互补
+func LoadImageOptions() ImageOptions {
+	return ImageOptions{
+		Quality:             envOrDefaultInt("IMAGE_QUALITY", 0),
+		KeepOriginal:        envOrDefaultBool("IMAGE_KEEP_ORIGINAL", false),
+		AutoUpload:          envOrDefaultBool("IMAGE_AUTO_UPLOAD", false),
+		CloudOptions:        loadCloudOptions(),
+		MaxWidth:            envOrDefaultInt("IMAGE_MAX_WIDTH", 0),
+		MaxHeight:           envOrDefaultInt("IMAGE_MAX_HEIGHT", 0),
+		StripEXIFOnCompress: envOrDefaultBool("IMAGE_STRIP_EXIF", false),
+	}
+}
+
+// loadCloudOptions 从环境变量加载云存储配置，供 NewMediaStore 选择 S3/BunnyCDN/本地后端使用
+func loadCloudOptions() CloudOptions {
+	return CloudOptions{
+		Type:     os.Getenv("CLOUD_TYPE"),
+		Address:  os.Getenv("CLOUD_ADDRESS"),
+		Account:  os.Getenv("CLOUD_ACCOUNT"),
+		Password: os.Getenv("CLOUD_PASSWORD"),
+		Token:    os.Getenv("CLOUD_TOKEN"),
+		Bucket:   os.Getenv("CLOUD_BUCKET"),
+		Region:   os.Getenv("CLOUD_REGION"),
+		Path:     os.Getenv("CLOUD_PATH"),
+	}
+}
+
+func envOrDefaultInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+func envOrDefaultBool(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// BuildProviders 按配置依次注册压缩提供方；compressImages 会按此顺序尝试，失败则回退到下一个。
+// LocalCompressor 不依赖外部服务，始终作为最后一个兜底提供方。keyPool 在存在可用 Tinify Key 时非空，
+// 供调用方在压缩完成后打印每个 Key 的用量报告。
+func BuildProviders(cfg ProviderConfig, imageOptions ImageOptions) (providers []Compressor, keyPool *TinifyKeyPool) {
+	if pool, err := LoadTinifyKeyPool(cfg.TinifyKeysFile); err == nil && pool.Len() > 0 {
+		keyPool = pool
+		providers = append(providers, NewTinifyCompressor(pool, imageOptions))
+	}
+	if cfg.ShortPixelAPIKey != "" {
+		providers = append(providers, NewShortPixelCompressor(cfg.ShortPixelAPIKey))
+	}
+	if cfg.CloudflareAccountID != "" && cfg.CloudflareAPIToken != "" {
+		providers = append(providers, NewCloudflareImagesCompressor(cfg.CloudflareAccountID, cfg.CloudflareAPIToken))
+	}
+	providers = append(providers, NewLocalCompressor(imageOptions))
+	return providers, keyPool
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// GetFileMeta 读取图片的宽高以及 EXIF 元信息。文件没有 EXIF 数据（如截图、无 EXIF 的 PNG）时
+// 只返回宽高，不视为错误；GPS 经纬度通过 exif 库的 LatLong 直接得到带符号的十进制度数。
+func GetFileMeta(file string) (map[string]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := map[string]string{
+		"Width":  strconv.Itoa(cfg.Width),
+		"Height": strconv.Itoa(cfg.Height),
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	x, err := exif.Decode(f)
+	if err != nil {
+		return meta, nil
+	}
+
+	if v, ok := exifString(x, exif.Make); ok {
+		meta["Make"] = v
+	}
+	if v, ok := exifString(x, exif.Model); ok {
+		meta["Model"] = v
+	}
+	if v, ok := exifString(x, exif.DateTimeOriginal); ok {
+		meta["DateTimeOriginal"] = v
+	}
+	if v, ok := exifInt(x, exif.Orientation); ok {
+		meta["Orientation"] = strconv.Itoa(v)
+	}
+	if v, ok := exifInt(x, exif.ColorSpace); ok {
+		meta["ColorSpace"] = strconv.Itoa(v)
+	}
+	if lat, lon, err := x.LatLong(); err == nil {
+		meta["GPSLatitude"] = strconv.FormatFloat(lat, 'f', 6, 64)
+		meta["GPSLongitude"] = strconv.FormatFloat(lon, 'f', 6, 64)
+	}
+
+	return meta, nil
+}
+
+func exifString(x *exif.Exif, name exif.FieldName) (string, bool) {
+	tag, err := x.Get(name)
+	if err != nil {
+		return "", false
+	}
+	v, err := tag.StringVal()
+	if err != nil {
+		return "", false
+	}
+	return v, true
+}
+
+func exifInt(x *exif.Exif, name exif.FieldName) (int, bool) {
+	tag, err := x.Get(name)
+	if err != nil {
+		return 0, false
+	}
+	v, err := tag.Int(0)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// stripEXIF 解码并重新编码图片，去掉 EXIF 等元数据；用于 ImageOptions.StripEXIFOnCompress 开启时
+// 在压缩结果的基础上再做一次隐私清理
+func stripEXIF(data []byte, ext string) ([]byte, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	switch strings.ToLower(ext) {
+	case ".png":
+		err = png.Encode(&buf, img)
+	case ".jpg", ".jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90})
+	default:
+		return nil, fmt.Errorf("不支持去除元数据的图片类型：%s", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -1,16 +1,19 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"crypto/md5"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
-	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 )
@@ -146,12 +149,12 @@ func GetFileMD5(file string) (string, error) {
 		return "", err
 	}
 
-	return fmt.Sprintf("%x", md5.Sum(nil)), nil
+	return fmt.Sprintf("%x", md5Hash.Sum(nil)), nil
 }
 
-func GetFileMeta(file string) (map[string]string, error) {
-	// todo: 获取文件元信息 https://github.com/rwcarlsen/goexif
-	return nil, nil
+// md5Hex 计算内存中数据的 MD5，供 AutoUpload 场景下无本地文件可 stat 时使用
+func md5Hex(data []byte) string {
+	return fmt.Sprintf("%x", md5.Sum(data))
 }
 
 type FileIterator[T any] func(file string) T
@@ -174,6 +177,14 @@ func IterateFiles[T any](dir string, iterator FileIterator[T]) []T {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		if err := runServe(); err != nil {
+			fmt.Println("启动服务失败：", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	dir, err := os.Getwd()
 	if err != nil {
 		panic(err)
@@ -214,57 +225,172 @@ func main() {
 	printPrettyInfo(pngFiles, options)
 
 	// 压缩图片
+	providerConfig := LoadProviderConfig()
+	imageOptions := LoadImageOptions()
+	providers, tinifyKeys := BuildProviders(providerConfig, imageOptions)
+	store, err := NewMediaStore(imageOptions.CloudOptions)
+	if err != nil {
+		fmt.Println("初始化存储失败：", err)
+		return
+	}
 	compressionOptions := CompressionOptions{
 		CompressImage: true,
+		ImageOptions:  imageOptions,
+		Providers:     providers,
+		Concurrency:   providerConfig.Concurrency,
+		TinifyKeys:    tinifyKeys,
+		Store:         store,
 	}
 	if compressionOptions.CompressImage {
-		compressedJpgFiles, compressedPngFiles, err2 := compressImages(jpgFiles, pngFiles, compressionOptions)
-		if err2 != nil {
-			fmt.Println("压缩失败：", err2)
-			return
+		compressedJpgFiles, compressedPngFiles, errs := compressImages(jpgFiles, pngFiles, compressionOptions)
+		for _, fileErr := range errs {
+			fmt.Println("压缩失败：", fileErr)
 		}
-		printPrettyInfo(compressedJpgFiles, options)
-		printPrettyInfo(compressedPngFiles, options)
+		printCompressedResults(compressedJpgFiles)
+		printCompressedResults(compressedPngFiles)
 	}
 }
 
-func compressImages(jpgFiles, pngFiles []string, options CompressionOptions) (compressedJpgFiles, compressedPngFiles []string, err error) {
-	// 创建图片压缩器
-	compressor := &DefaultImageCompressor{
-		Options: options.ImageOptions,
-	}
+// compressJob 是提交给压缩 worker 池的一个文件压缩任务
+type compressJob struct {
+	file  string
+	isPNG bool
+}
+
+// CompressedResult 是压缩（以及按需上传）完成后单个文件的展示信息
+type CompressedResult struct {
+	Name string
+	Size int64
+	MD5  string
+	// URL 仅在 ImageOptions.AutoUpload 开启且上传成功时非空
+	URL string
+}
+
+// compressJobResult 是 worker 处理完一个 compressJob 后产出的结果
+type compressJobResult struct {
+	file   string
+	isPNG  bool
+	result CompressedResult
+	err    error
+}
 
-	fOption := FilterOptions{
-		GetFileSize: true,
-		GetFileMD5:  true,
+// shouldKeepOriginal 判断压缩结果是否应当被丢弃、保留原图：压缩后体积没有变小时不值得替换原图
+func shouldKeepOriginal(originalSize, compressedSize int64) bool {
+	return compressedSize >= originalSize
+}
+
+// compressImages 使用 Concurrency 个 worker 并发压缩 jpgFiles 和 pngFiles，
+// 每个任务都会走配置好的多提供方回退管线；当 AutoUpload 开启时压缩结果直接从内存上传到 Store，
+// 否则落地到本地 compressed/ 目录。压缩结束后打印 Tinify 各 Key 的用量与总耗时。
+// 单个文件失败不影响其余文件：失败的文件记录在 errs 里，成功的文件仍然出现在对应的结果切片中。
+func compressImages(jpgFiles, pngFiles []string, options CompressionOptions) (compressedJpgFiles, compressedPngFiles []CompressedResult, errs []error) {
+	start := time.Now()
+	ctx := context.Background()
+	pipeline := NewCompressionPipeline(options.Providers...)
+	// AutoUpload 关闭时，无论 options.Store 配置成什么云后端，都只落地到本地 compressed/ 目录
+	localStore := NewLocalStore("")
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	jobs := make(chan compressJob)
+	results := make(chan compressJobResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				var buf bytes.Buffer
+				if _, err := pipeline.Compress(ctx, job.file, &buf); err != nil {
+					results <- compressJobResult{file: job.file, isPNG: job.isPNG, err: err}
+					continue
+				}
+
+				if options.ImageOptions.StripEXIFOnCompress {
+					if stripped, err := stripEXIF(buf.Bytes(), filepath.Ext(job.file)); err == nil {
+						buf = *bytes.NewBuffer(stripped)
+					}
+				}
+
+				if srcInfo, err := os.Stat(job.file); err == nil && shouldKeepOriginal(srcInfo.Size(), int64(buf.Len())) {
+					slog.Info("压缩结果未小于原图，保留原图", "file", job.file, "originalSize", srcInfo.Size(), "compressedSize", buf.Len())
+					md5Sum, err := GetFileMD5(job.file)
+					if err != nil {
+						results <- compressJobResult{file: job.file, isPNG: job.isPNG, err: err}
+						continue
+					}
+					result := CompressedResult{Name: srcInfo.Name(), Size: srcInfo.Size(), MD5: md5Sum}
+					if options.ImageOptions.AutoUpload {
+						data, err := os.ReadFile(job.file)
+						if err != nil {
+							results <- compressJobResult{file: job.file, isPNG: job.isPNG, err: err}
+							continue
+						}
+						url, err := options.Store.Save(ctx, srcInfo.Name(), bytes.NewReader(data), mimeTypeForExt(filepath.Ext(job.file)))
+						if err != nil {
+							results <- compressJobResult{file: job.file, isPNG: job.isPNG, err: err}
+							continue
+						}
+						result.URL = url
+					}
+					results <- compressJobResult{file: job.file, isPNG: job.isPNG, result: result}
+					continue
+				}
+
+				name := filepath.Base(job.file)
+				var store MediaStore = localStore
+				if options.ImageOptions.AutoUpload {
+					store = options.Store
+				}
+				url, err := store.Save(ctx, name, bytes.NewReader(buf.Bytes()), mimeTypeForExt(filepath.Ext(name)))
+				if err != nil {
+					results <- compressJobResult{file: job.file, isPNG: job.isPNG, err: err}
+					continue
+				}
+
+				result := CompressedResult{Name: name, Size: int64(buf.Len()), MD5: md5Hex(buf.Bytes())}
+				if options.ImageOptions.AutoUpload {
+					result.URL = url
+				}
+				results <- compressJobResult{file: job.file, isPNG: job.isPNG, result: result}
+			}
+		}()
 	}
 
-	// 压缩 JPG 文件
-	for _, file := range jpgFiles {
-		fileInfo, err1 := GetFileInfo(file, fOption)
-		if err1 != nil {
-			return compressedJpgFiles, compressedPngFiles, err1
+	go func() {
+		for _, file := range jpgFiles {
+			jobs <- compressJob{file: file}
 		}
-		image, err1 := compressor.compressImage(file, &fileInfo)
-		if err1 != nil {
-			return compressedJpgFiles, compressedPngFiles, err1
+		for _, file := range pngFiles {
+			jobs <- compressJob{file: file, isPNG: true}
 		}
-		compressedJpgFiles = append(compressedJpgFiles, image)
-	}
+		close(jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
 
-	// 压缩 PNG 文件
-	for _, file := range pngFiles {
-		fileInfo, err1 := GetFileInfo(file, fOption)
-		if err1 != nil {
-			return compressedJpgFiles, compressedPngFiles, err1
+	for res := range results {
+		if res.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.file, res.err))
+			continue
 		}
-		image, err1 := compressor.compressImage(file, &fileInfo)
-		if err1 != nil {
-			return compressedJpgFiles, compressedPngFiles, err1
+		if res.isPNG {
+			compressedPngFiles = append(compressedPngFiles, res.result)
+		} else {
+			compressedJpgFiles = append(compressedJpgFiles, res.result)
 		}
-		compressedPngFiles = append(compressedPngFiles, image)
 	}
-	return compressedJpgFiles, compressedPngFiles, nil
+
+	printTinifyUsageReport(options.TinifyKeys, time.Since(start))
+
+	return compressedJpgFiles, compressedPngFiles, errs
 }
 
 func printPrettyInfo(files []string, options FilterOptions) {
@@ -303,7 +429,58 @@ func printPrettyInfo(files []string, options FilterOptions) {
 			row = append(row, fileInfo.MD5)
 		}
 		if options.GetFileMeta {
-			row = append(row, fileInfo.Meta)
+			row = append(row, renderMetaSubTable(fileInfo.Meta))
+		}
+		t.AppendRow(row)
+	}
+	t.Render()
+}
+
+// renderMetaSubTable 把 GetFileMeta 返回的 map 渲染成一张按字段名排序的嵌套表格，
+// 避免在“文件元信息”列里直接打印 Go map 的原始格式
+func renderMetaSubTable(meta map[string]string) string {
+	if len(meta) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"字段", "值"})
+	for _, k := range keys {
+		t.AppendRow(table.Row{k, meta[k]})
+	}
+	return t.Render()
+}
+
+// printCompressedResults 打印压缩结果；只要有任意一条记录带 URL（AutoUpload 开启且上传成功），
+// 就额外展示“上传地址”这一列
+func printCompressedResults(results []CompressedResult) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+
+	hasURL := false
+	for _, r := range results {
+		if r.URL != "" {
+			hasURL = true
+			break
+		}
+	}
+
+	header := table.Row{"#", "文件名", "文件大小", "文件MD5"}
+	if hasURL {
+		header = append(header, "上传地址")
+	}
+	t.AppendHeader(header)
+
+	for i, r := range results {
+		row := table.Row{i + 1, r.Name, humanSize(r.Size), r.MD5}
+		if hasURL {
+			row = append(row, r.URL)
 		}
 		t.AppendRow(row)
 	}
@@ -315,6 +492,14 @@ type CompressionOptions struct {
 	CompressImage bool
 	// 图片压缩选项
 	ImageOptions ImageOptions
+	// Providers 按顺序尝试的压缩提供方，前一个失败则回退到下一个
+	Providers []Compressor
+	// Concurrency 并发压缩的 worker 数量，默认为 1
+	Concurrency int
+	// TinifyKeys 本次压缩使用的 Tinify Key 池，压缩结束后用于打印用量报告；未配置 Tinify 时为 nil
+	TinifyKeys *TinifyKeyPool
+	// Store 压缩结果的持久化目的地；AutoUpload 开启时结果 URL 来自这里
+	Store MediaStore
 }
 
 type CloudOptions struct {
@@ -336,6 +521,12 @@ type CloudOptions struct {
 	Path string
 }
 
+// 压缩器对过大的图片做 resize 时使用的默认上限
+const (
+	defaultMaxWidth  = 2000
+	defaultMaxHeight = 3000
+)
+
 type ImageOptions struct {
 	// 压缩质量
 	Quality int
@@ -345,28 +536,12 @@ type ImageOptions struct {
 	AutoUpload bool
 	// 云存储选项
 	CloudOptions CloudOptions
-	// 图片压缩器
-	Compressor FileCompressor
-}
-
-// FileCompressor 文件压缩器
-type FileCompressor interface {
-	// compressImage 压缩图片，返回压缩后的图片路径；如果压缩失败，返回错误；
-	compressImage(file string, fileInfo *FileInfo) (string, error)
-}
-
-type DefaultImageCompressor struct {
-	// 图片压缩选项
-	Options ImageOptions
-}
-
-func (i *DefaultImageCompressor) compressImage(file string, fileInfo *FileInfo) (string, error) {
-	if filepath.Ext(file) == ".jpg" || filepath.Ext(file) == ".jpeg" {
-		return tinyPngCompress(file, "compressed/"+filepath.Base(file))
-	} else if filepath.Ext(file) == ".png" {
-		return tinyPngCompress(file, "compressed/"+filepath.Base(file))
-	}
-	return "", fmt.Errorf("不支持的图片类型：%s", file)
+	// MaxWidth 超出时触发 resize，<=0 时使用 defaultMaxWidth
+	MaxWidth int
+	// MaxHeight 超出时触发 resize，<=0 时使用 defaultMaxHeight
+	MaxHeight int
+	// StripEXIFOnCompress 压缩后是否重新编码以去除 EXIF 等元数据，用于隐私场景
+	StripEXIFOnCompress bool
 }
 
 // humanSize 格式化文件大小
@@ -383,96 +558,6 @@ func humanSize(size int64) string {
 	return fmt.Sprintf("%.2f %cB", float64(size)/float64(div), "KMGTPE"[exp])
 }
 
-const (
-	// 替换为你的 API 密钥
-	apiKey = "----"
-
-	// 上传图片 URL
-	uploadUrl = "https://api.tinify.com/shrink"
-)
-
-func tinyPngCompress(input, output string) (string, error) {
-	// 读取本地图片
-	file, err := os.Open(input)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	// 创建 HTTP 请求
-	req, err := http.NewRequest("POST", uploadUrl, nil)
-	if err != nil {
-		return "", err
-	}
-
-	// 设置请求头
-	req.SetBasicAuth("api", apiKey)
-
-	req.Body = io.NopCloser(file)
-
-	// 发送请求
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	// 读取压缩后的图片
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
-	}
-
-	response := TinifyShrinkResponse{}
-	err = json.Unmarshal(data, &response)
-	if err != nil {
-		return "", err
-	}
-
-	if response.Error != "" {
-		return "", fmt.Errorf("压缩失败：%s", response.Message)
-	}
-
-	if response.Output.URL == "" {
-		return "", fmt.Errorf("压缩失败：%s", response.Message)
-	}
-
-	fResponse, err := http.DefaultClient.Get(response.Output.URL)
-	if err != nil {
-		return "", err
-	}
-	defer fResponse.Body.Close()
-	data, err = io.ReadAll(fResponse.Body)
-	if err != nil {
-		return "", err
-	}
-
-	// 保存压缩后的图片
-	err = SaveFile(output, data)
-	return output, err
-}
-
-type TinifyShrinkResponse struct {
-	Input   *Input  `json:"input,omitempty"`
-	Output  *Output `json:"output,omitempty"`
-	Message string  `json:"message,omitempty"`
-	Error   string  `json:"error,omitempty"`
-}
-
-type Input struct {
-	Size int    `json:"size"`
-	Type string `json:"type"`
-}
-
-type Output struct {
-	Size   int     `json:"size"`
-	Type   string  `json:"type"`
-	Width  int     `json:"width"`
-	Height int     `json:"height"`
-	Ratio  float64 `json:"ratio"`
-	URL    string  `json:"url"`
-}
-
 func SaveFile(fileName string, data []byte) error {
 	dir := filepath.Dir(fileName)
 	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
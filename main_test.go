@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestShouldKeepOriginal(t *testing.T) {
+	cases := []struct {
+		name           string
+		originalSize   int64
+		compressedSize int64
+		want           bool
+	}{
+		{name: "压缩后变小", originalSize: 1000, compressedSize: 500, want: false},
+		{name: "压缩后变大", originalSize: 500, compressedSize: 1000, want: true},
+		{name: "压缩后大小不变", originalSize: 500, compressedSize: 500, want: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := shouldKeepOriginal(tc.originalSize, tc.compressedSize); got != tc.want {
+				t.Errorf("shouldKeepOriginal(%d, %d) = %v, want %v", tc.originalSize, tc.compressedSize, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// cloudflareImagesURLFormat Cloudflare Images 上传接口地址模板
+const cloudflareImagesURLFormat = "https://api.cloudflare.com/client/v4/accounts/%s/images/v1"
+
+// CloudflareImagesCompressor 上传到 Cloudflare Images，并将其自动生成的变体作为压缩结果取回
+type CloudflareImagesCompressor struct {
+	AccountID string
+	APIToken  string
+}
+
+// NewCloudflareImagesCompressor 使用给定的账户与 API Token 创建 Cloudflare Images 提供方
+func NewCloudflareImagesCompressor(accountID, apiToken string) *CloudflareImagesCompressor {
+	return &CloudflareImagesCompressor{AccountID: accountID, APIToken: apiToken}
+}
+
+func (c *CloudflareImagesCompressor) Name() string {
+	return "CloudflareImages"
+}
+
+func (c *CloudflareImagesCompressor) Compress(ctx context.Context, srcPath string, dst io.Writer) (Result, error) {
+	file, err := os.Open(srcPath)
+	if err != nil {
+		return Result{}, err
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filepath.Base(srcPath))
+	if err != nil {
+		return Result{}, err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return Result{}, err
+	}
+	if err := writer.Close(); err != nil {
+		return Result{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(cloudflareImagesURLFormat, c.AccountID), &body)
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+c.APIToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+
+	var uploadResp cloudflareUploadResponse
+	if err := json.NewDecoder(resp.Body).Decode(&uploadResp); err != nil {
+		return Result{}, err
+	}
+	if !uploadResp.Success || len(uploadResp.Result.Variants) == 0 {
+		return Result{}, fmt.Errorf("cloudflare images 上传失败：%v", uploadResp.Errors)
+	}
+
+	outResp, err := http.Get(uploadResp.Result.Variants[0])
+	if err != nil {
+		return Result{}, err
+	}
+	defer outResp.Body.Close()
+
+	size, err := io.Copy(dst, outResp.Body)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{Size: size}, nil
+}
+
+type cloudflareUploadResponse struct {
+	Success bool `json:"success"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+	Result struct {
+		Variants []string `json:"variants"`
+	} `json:"result"`
+}
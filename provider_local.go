@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+// LocalCompressor 离线压缩器，不依赖任何外部服务，可作为其它提供方不可用时的最终兜底
+type LocalCompressor struct {
+	Options ImageOptions
+}
+
+// NewLocalCompressor 基于图片压缩选项创建本地压缩提供方
+func NewLocalCompressor(options ImageOptions) *LocalCompressor {
+	return &LocalCompressor{Options: options}
+}
+
+func (c *LocalCompressor) Name() string {
+	return "Local"
+}
+
+func (c *LocalCompressor) Compress(ctx context.Context, srcPath string, dst io.Writer) (Result, error) {
+	src, err := imaging.Open(srcPath)
+	if err != nil {
+		return Result{}, err
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	maxWidth := c.Options.MaxWidth
+	if maxWidth <= 0 {
+		maxWidth = defaultMaxWidth
+	}
+	maxHeight := c.Options.MaxHeight
+	if maxHeight <= 0 {
+		maxHeight = defaultMaxHeight
+	}
+	if width > maxWidth || height > maxHeight {
+		src = imaging.Fit(src, maxWidth, maxHeight, imaging.Lanczos)
+		bounds = src.Bounds()
+		width, height = bounds.Dx(), bounds.Dy()
+	}
+
+	quality := c.Options.Quality
+	if quality <= 0 {
+		quality = 80
+	}
+
+	cw := &countingWriter{}
+	switch strings.ToLower(filepath.Ext(srcPath)) {
+	case ".png":
+		err = png.Encode(cw, src)
+	case ".jpg", ".jpeg":
+		err = jpeg.Encode(cw, src, &jpeg.Options{Quality: quality})
+	default:
+		return Result{}, fmt.Errorf("本地压缩器不支持的图片类型：%s", srcPath)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	if _, err := dst.Write(cw.buf); err != nil {
+		return Result{}, err
+	}
+
+	return Result{Size: cw.count, Width: width, Height: height}, nil
+}
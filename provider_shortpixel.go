@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// shortPixelReduceURL ShortPixel 压缩接口地址
+const shortPixelReduceURL = "https://api.shortpixel.com/v2/reducer.json"
+
+// ShortPixelCompressor 使用 ShortPixel API 压缩图片
+type ShortPixelCompressor struct {
+	APIKey string
+}
+
+// NewShortPixelCompressor 使用给定的 API Key 创建 ShortPixel 提供方
+func NewShortPixelCompressor(apiKey string) *ShortPixelCompressor {
+	return &ShortPixelCompressor{APIKey: apiKey}
+}
+
+func (c *ShortPixelCompressor) Name() string {
+	return "ShortPixel"
+}
+
+func (c *ShortPixelCompressor) Compress(ctx context.Context, srcPath string, dst io.Writer) (Result, error) {
+	file, err := os.Open(srcPath)
+	if err != nil {
+		return Result{}, err
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("key", c.APIKey); err != nil {
+		return Result{}, err
+	}
+	if err := writer.WriteField("lossy", "1"); err != nil {
+		return Result{}, err
+	}
+	part, err := writer.CreateFormFile("file", filepath.Base(srcPath))
+	if err != nil {
+		return Result{}, err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return Result{}, err
+	}
+	if err := writer.Close(); err != nil {
+		return Result{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, shortPixelReduceURL, &body)
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+
+	var results []shortPixelResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return Result{}, err
+	}
+	if len(results) == 0 {
+		return Result{}, fmt.Errorf("shortpixel 压缩失败：响应为空")
+	}
+
+	result := results[0]
+	if result.Status.Code != 2 {
+		return Result{}, fmt.Errorf("shortpixel 压缩失败：%s", result.Status.Message)
+	}
+
+	outResp, err := http.Get(result.LossyURL)
+	if err != nil {
+		return Result{}, err
+	}
+	defer outResp.Body.Close()
+
+	size, err := io.Copy(dst, outResp.Body)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{Size: size}, nil
+}
+
+type shortPixelResult struct {
+	Status struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"Status"`
+	LossyURL string `json:"LossyURL"`
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// tinifyShrinkURL Tinify/TinyPNG 压缩接口地址
+const tinifyShrinkURL = "https://api.tinify.com/shrink"
+
+// errTinifyKeyExhausted 表示当前 Key 已失效或超出月度额度，调用方应换下一个 Key 重试
+var errTinifyKeyExhausted = errors.New("tinify key 已失效或超出月度额度")
+
+// TinifyCompressor 使用 Tinify（TinyPNG）API 压缩图片，从 Keys 中轮换获取可用的 API Key
+type TinifyCompressor struct {
+	Keys *TinifyKeyPool
+	// Options 提供 MaxWidth/MaxHeight，超出时在 Tinify 端一次往返完成 resize
+	Options ImageOptions
+}
+
+// NewTinifyCompressor 基于一个 Key 池和压缩选项创建 Tinify 提供方
+func NewTinifyCompressor(keys *TinifyKeyPool, options ImageOptions) *TinifyCompressor {
+	return &TinifyCompressor{Keys: keys, Options: options}
+}
+
+func (c *TinifyCompressor) Name() string {
+	return "Tinify"
+}
+
+func (c *TinifyCompressor) Compress(ctx context.Context, srcPath string, dst io.Writer) (Result, error) {
+	attempts := c.Keys.Len()
+	if attempts == 0 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for i := 0; i < attempts; i++ {
+		key, err := c.Keys.Acquire()
+		if err != nil {
+			return Result{}, err
+		}
+
+		result, err := c.compressWithKey(ctx, key, srcPath, dst)
+		if err == nil {
+			return result, nil
+		}
+		if !errors.Is(err, errTinifyKeyExhausted) {
+			return Result{}, err
+		}
+		lastErr = err
+	}
+	return Result{}, fmt.Errorf("tinify 压缩失败，已尝试所有 key：%w", lastErr)
+}
+
+// compressWithKey 使用单个 Key 发起一次压缩请求；Key 失效或超额时返回 errTinifyKeyExhausted 以便上层换 Key 重试。
+// 源文件一次性读入内存后以已知 Content-Length 流式提交，超出 MaxWidth/MaxHeight 时再向 /shrink 返回的
+// Location 地址追加一次 resize 请求，全程不落地任何临时文件。
+func (c *TinifyCompressor) compressWithKey(ctx context.Context, key, srcPath string, dst io.Writer) (Result, error) {
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		return Result{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tinifyShrinkURL, bytes.NewReader(data))
+	if err != nil {
+		return Result{}, err
+	}
+	req.ContentLength = int64(len(data))
+	req.SetBasicAuth("api", key)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+
+	if count, err := strconv.Atoi(resp.Header.Get("Compression-Count")); err == nil {
+		c.Keys.RecordUsage(key, count)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusUnauthorized {
+		c.Keys.MarkInvalid(key)
+		return Result{}, errTinifyKeyExhausted
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var shrinkResp TinifyShrinkResponse
+	if err := json.Unmarshal(body, &shrinkResp); err != nil {
+		return Result{}, err
+	}
+	if shrinkResp.Error != "" {
+		return Result{}, fmt.Errorf("tinify 压缩失败：%s", shrinkResp.Message)
+	}
+
+	location := resp.Header.Get("Location")
+	if location == "" && shrinkResp.Output != nil {
+		location = shrinkResp.Output.URL
+	}
+	if location == "" {
+		return Result{}, fmt.Errorf("tinify 压缩失败：%s", shrinkResp.Message)
+	}
+
+	width, height := 0, 0
+	if shrinkResp.Output != nil {
+		width, height = shrinkResp.Output.Width, shrinkResp.Output.Height
+	}
+
+	maxWidth := c.Options.MaxWidth
+	if maxWidth <= 0 {
+		maxWidth = defaultMaxWidth
+	}
+	maxHeight := c.Options.MaxHeight
+	if maxHeight <= 0 {
+		maxHeight = defaultMaxHeight
+	}
+
+	var result []byte
+	if width > maxWidth || height > maxHeight {
+		resizeReq, err := newTinifyResizeRequest(ctx, location, key, maxWidth, maxHeight)
+		if err != nil {
+			return Result{}, err
+		}
+		resizeResp, err := http.DefaultClient.Do(resizeReq)
+		if err != nil {
+			return Result{}, err
+		}
+		defer resizeResp.Body.Close()
+		if result, err = io.ReadAll(resizeResp.Body); err != nil {
+			return Result{}, err
+		}
+		width, height = 0, 0
+		if w, err := strconv.Atoi(resizeResp.Header.Get("Image-Width")); err == nil {
+			width = w
+		}
+		if h, err := strconv.Atoi(resizeResp.Header.Get("Image-Height")); err == nil {
+			height = h
+		}
+	} else {
+		outResp, err := http.Get(location)
+		if err != nil {
+			return Result{}, err
+		}
+		defer outResp.Body.Close()
+		if result, err = io.ReadAll(outResp.Body); err != nil {
+			return Result{}, err
+		}
+	}
+
+	size, err := io.Copy(dst, bytes.NewReader(result))
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{Size: size, Width: width, Height: height}, nil
+}
+
+// newTinifyResizeRequest 向 /shrink 返回的 Location 地址 POST {"resize":{"method":"fit",...}}，
+// 一次往返即可取回缩放后的结果，避免单独再发起一次下载再二次提交
+func newTinifyResizeRequest(ctx context.Context, location, key string, width, height int) (*http.Request, error) {
+	payload, err := json.Marshal(map[string]any{
+		"resize": map[string]any{
+			"method": "fit",
+			"width":  width,
+			"height": height,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, location, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth("api", key)
+	return req, nil
+}
+
+type TinifyShrinkResponse struct {
+	Input   *Input  `json:"input,omitempty"`
+	Output  *Output `json:"output,omitempty"`
+	Message string  `json:"message,omitempty"`
+	Error   string  `json:"error,omitempty"`
+}
+
+type Input struct {
+	Size int    `json:"size"`
+	Type string `json:"type"`
+}
+
+type Output struct {
+	Size   int     `json:"size"`
+	Type   string  `json:"type"`
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	Ratio  float64 `json:"ratio"`
+	URL    string  `json:"url"`
+}
+
+// printTinifyUsageReport 打印每个 Key 的用量以及本次压缩总耗时
+func printTinifyUsageReport(keys *TinifyKeyPool, elapsed time.Duration) {
+	if keys == nil {
+		return
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Tinify Key", "已用次数", "月度额度"})
+	for key, count := range keys.Usage() {
+		t.AppendRow(table.Row{maskTinifyKey(key), count, tinifyMonthlyFreeQuota})
+	}
+	t.AppendFooter(table.Row{"总耗时", elapsed.String(), ""})
+	t.Render()
+}
+
+// maskTinifyKey 只展示 Key 的首尾几位，避免把完整密钥打印到控制台
+func maskTinifyKey(key string) string {
+	if len(key) <= 8 {
+		return "****"
+	}
+	return key[:4] + "****" + key[len(key)-4:]
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"image"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// ServeOptions 配置 `serve` 子命令启动的 HTTP 服务
+type ServeOptions struct {
+	// Addr 监听地址，例如 ":8080"
+	Addr string
+	// BearerToken 非空时，所有请求都必须携带匹配的 Authorization: Bearer <token>
+	BearerToken string
+	// Compression 复用 CLI 模式下的压缩配置（Providers/Concurrency/TinifyKeys 等）
+	Compression CompressionOptions
+	// Store 上传文件的持久化目的地
+	Store MediaStore
+}
+
+// mediaResponse 是媒体上传与查询接口返回的 JSON 结构
+type mediaResponse struct {
+	URL    string `json:"url"`
+	Size   int64  `json:"size"`
+	MD5    string `json:"md5"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// runServe 组装 ServeOptions 并启动 HTTP 服务，供 `serve` 子命令使用
+func runServe() error {
+	providerConfig := LoadProviderConfig()
+	imageOptions := LoadImageOptions()
+	providers, tinifyKeys := BuildProviders(providerConfig, imageOptions)
+	store, err := NewMediaStore(imageOptions.CloudOptions)
+	if err != nil {
+		return err
+	}
+
+	return serve(ServeOptions{
+		Addr:        envOrDefault("SERVE_ADDR", ":8080"),
+		BearerToken: os.Getenv("SERVE_BEARER_TOKEN"),
+		Compression: CompressionOptions{
+			ImageOptions: imageOptions,
+			Providers:    providers,
+			Concurrency:  providerConfig.Concurrency,
+			TinifyKeys:   tinifyKeys,
+		},
+		Store: store,
+	})
+}
+
+// serve 启动一个长驻的 HTTP 服务，暴露类 Micropub 的媒体上传与查询接口
+func serve(opts ServeOptions) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /media", handleMediaUpload(opts))
+	mux.HandleFunc("GET /media", handleMediaInfo(opts))
+
+	if opts.BearerToken == "" {
+		slog.Warn("SERVE_BEARER_TOKEN 未设置，/media 接口不做任何鉴权")
+	}
+
+	server := &http.Server{
+		Addr:    opts.Addr,
+		Handler: withBearerAuth(opts.BearerToken, mux),
+	}
+
+	slog.Info("HTTP 服务已启动", "addr", opts.Addr)
+	return server.ListenAndServe()
+}
+
+// withBearerAuth 在 token 非空时要求请求携带匹配的 Authorization: Bearer <token>
+func withBearerAuth(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleMediaUpload 处理 POST /media：接收 multipart/form-data 的 file 字段，
+// 以内容的 SHA-256 生成内容寻址文件名，走压缩管线后交给 MediaStore 持久化
+func handleMediaUpload(opts ServeOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "缺少 file 字段："+err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(file)
+		if err != nil {
+			http.Error(w, "读取上传文件失败："+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		ext := filepath.Ext(header.Filename)
+		if ext == "" {
+			if exts, _ := mime.ExtensionsByType(header.Header.Get("Content-Type")); len(exts) > 0 {
+				ext = exts[0]
+			}
+		}
+
+		sum := sha256.Sum256(data)
+		name := hex.EncodeToString(sum[:]) + ext
+
+		tmpFile, err := os.CreateTemp("", "timage-upload-*"+ext)
+		if err != nil {
+			http.Error(w, "创建临时文件失败："+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer os.Remove(tmpFile.Name())
+		if _, err := tmpFile.Write(data); err != nil {
+			tmpFile.Close()
+			http.Error(w, "写入临时文件失败："+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		tmpFile.Close()
+
+		ctx := r.Context()
+		pipeline := NewCompressionPipeline(opts.Compression.Providers...)
+		var buf bytes.Buffer
+		if _, err := pipeline.Compress(ctx, tmpFile.Name(), &buf); err != nil {
+			http.Error(w, "压缩失败："+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if opts.Compression.ImageOptions.StripEXIFOnCompress {
+			if stripped, err := stripEXIF(buf.Bytes(), ext); err == nil {
+				buf = *bytes.NewBuffer(stripped)
+			}
+		}
+
+		compressed := buf.Bytes()
+		if len(compressed) >= len(data) {
+			slog.Info("压缩结果未小于原图，保留原图", "name", name, "originalSize", len(data), "compressedSize", len(compressed))
+			compressed = data
+		}
+		cfg, _, _ := image.DecodeConfig(bytes.NewReader(compressed))
+
+		url, err := opts.Store.Save(ctx, name, bytes.NewReader(compressed), mimeTypeForExt(ext))
+		if err != nil {
+			http.Error(w, "保存失败："+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		resp := mediaResponse{
+			URL:    url,
+			Size:   int64(len(compressed)),
+			MD5:    md5Hex(compressed),
+			Width:  cfg.Width,
+			Height: cfg.Height,
+		}
+		w.Header().Set("Location", url)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// handleMediaInfo 处理 GET /media?name=...：按本服务此前生成的内容寻址文件名，
+// 通过 MediaStore 回源读取，返回大小、MD5 与宽高等元信息；不接受任意 URL 或本地路径
+func handleMediaInfo(opts ServeOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		if name == "" || filepath.Base(name) != name {
+			http.Error(w, "缺少合法的 name 参数", http.StatusBadRequest)
+			return
+		}
+
+		rc, err := opts.Store.Open(r.Context(), name)
+		if err != nil {
+			http.Error(w, "读取失败："+err.Error(), http.StatusNotFound)
+			return
+		}
+		defer rc.Close()
+
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			http.Error(w, "读取失败："+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		cfg, _, _ := image.DecodeConfig(bytes.NewReader(data))
+		resp := mediaResponse{
+			URL:    name,
+			Size:   int64(len(data)),
+			MD5:    md5Hex(data),
+			Width:  cfg.Width,
+			Height: cfg.Height,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
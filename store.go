@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MediaStore 持久化压缩后的图片字节，返回可公开访问的 URL
+type MediaStore interface {
+	Save(ctx context.Context, name string, r io.Reader, mime string) (url string, err error)
+	// Open 按 Save 时使用的 name 回源读取已存储的内容，用于按名查询元信息。
+	// name 必须是本服务此前生成的内容寻址文件名，调用方不应据此做任意路径/URL 解析
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+}
+
+// LocalStore 将文件保存到本地目录，保持压缩管线原有的 compressed/ 行为
+type LocalStore struct {
+	Dir string
+}
+
+// NewLocalStore 创建一个保存到 dir 的本地存储；dir 为空时默认使用 compressed/
+func NewLocalStore(dir string) *LocalStore {
+	if dir == "" {
+		dir = "compressed"
+	}
+	return &LocalStore{Dir: dir}
+}
+
+func (s *LocalStore) Save(ctx context.Context, name string, r io.Reader, mime string) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(s.Dir, name)
+	if err := SaveFile(path, data); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func (s *LocalStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	if filepath.Base(name) != name {
+		return nil, fmt.Errorf("非法文件名：%s", name)
+	}
+	return os.Open(filepath.Join(s.Dir, name))
+}
+
+// NewMediaStore 根据 CloudOptions.Type 创建对应的 MediaStore 实现；
+// Type 为空或 "local" 时回退到本地文件系统，保持现有行为不变
+func NewMediaStore(opts CloudOptions) (MediaStore, error) {
+	switch opts.Type {
+	case "s3":
+		return NewS3Store(opts)
+	case "bunny":
+		return NewBunnyStore(opts), nil
+	case "", "local":
+		return NewLocalStore(opts.Path), nil
+	default:
+		return nil, fmt.Errorf("不支持的云存储类型：%s", opts.Type)
+	}
+}
+
+// mimeTypeForExt 根据文件扩展名猜测 MIME 类型，仅覆盖本工具支持的图片格式
+func mimeTypeForExt(ext string) string {
+	switch strings.ToLower(ext) {
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".png":
+		return "image/png"
+	default:
+		return "application/octet-stream"
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// BunnyStore 通过简单的 PUT 请求将文件上传到 BunnyCDN Storage Zone
+type BunnyStore struct {
+	// StorageName 对应 BunnyStorageName，即 Storage Zone 名称
+	StorageName string
+	// StorageKey 对应 BunnyStorageKey，即 Storage Zone 的访问密钥
+	StorageKey string
+	// Region Bunny 存储区域代码，留空则使用默认的 storage.bunnycdn.com
+	Region string
+	// PullZone 拉取区域域名，用于拼接最终可公开访问的 URL；留空则直接返回存储接口地址
+	PullZone string
+}
+
+// NewBunnyStore 基于 CloudOptions 创建一个 BunnyCDN Storage 客户端：
+// Account/Token 对应 BunnyStorageName/BunnyStorageKey，Region 为存储区域代码，Address 为 Pull Zone 域名
+func NewBunnyStore(opts CloudOptions) *BunnyStore {
+	return &BunnyStore{
+		StorageName: opts.Account,
+		StorageKey:  opts.Token,
+		Region:      opts.Region,
+		PullZone:    opts.Address,
+	}
+}
+
+func (s *BunnyStore) endpoint() string {
+	host := "storage.bunnycdn.com"
+	if s.Region != "" {
+		host = s.Region + "." + host
+	}
+	return fmt.Sprintf("https://%s/%s", host, s.StorageName)
+}
+
+func (s *BunnyStore) Save(ctx context.Context, name string, r io.Reader, mime string) (string, error) {
+	uploadURL := fmt.Sprintf("%s/%s", s.endpoint(), name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, r)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("AccessKey", s.StorageKey)
+	req.Header.Set("Content-Type", mime)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("bunny storage 上传失败，状态码：%d", resp.StatusCode)
+	}
+
+	if s.PullZone != "" {
+		return fmt.Sprintf("https://%s/%s", s.PullZone, name), nil
+	}
+	return uploadURL, nil
+}
+
+func (s *BunnyStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	downloadURL := fmt.Sprintf("%s/%s", s.endpoint(), name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("AccessKey", s.StorageKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("bunny storage 下载失败，状态码：%d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
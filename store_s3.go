@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3Store 将文件上传到 S3 兼容的对象存储，覆盖 AWS S3 以及 MinIO 等自建服务
+type S3Store struct {
+	Bucket   string
+	Endpoint string
+	Region   string
+	client   *s3.Client
+}
+
+// NewS3Store 基于 CloudOptions 创建一个 S3 兼容对象存储客户端：
+// Account/Password 对应 AccessKeyID/SecretAccessKey，Address 为自定义 Endpoint（MinIO 等），
+// 留空时使用 AWS 默认的公有云 S3 endpoint
+func NewS3Store(opts CloudOptions) (*S3Store, error) {
+	cfg, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRegion(opts.Region),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(opts.Account, opts.Password, "")),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if opts.Address != "" {
+			o.BaseEndpoint = aws.String(opts.Address)
+			o.UsePathStyle = true
+		}
+	})
+
+	return &S3Store{Bucket: opts.Bucket, Endpoint: opts.Address, Region: opts.Region, client: client}, nil
+}
+
+func (s *S3Store) Save(ctx context.Context, name string, r io.Reader, mime string) (string, error) {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.Bucket),
+		Key:         aws.String(name),
+		Body:        r,
+		ContentType: aws.String(mime),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if s.Endpoint != "" {
+		return fmt.Sprintf("%s/%s/%s", s.Endpoint, s.Bucket, name), nil
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.Bucket, s.Region, name), nil
+}
+
+func (s *S3Store) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
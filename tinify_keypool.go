@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// tinifyMonthlyFreeQuota Tinify 每个 Key 每月的免费压缩次数
+const tinifyMonthlyFreeQuota = 500
+
+// TinifyKeyPool 管理一组 Tinify API Key，在某个 Key 达到月度免费额度或失效后自动轮换到下一个
+type TinifyKeyPool struct {
+	mu      sync.Mutex
+	keys    []string
+	next    int
+	usage   map[string]int
+	invalid map[string]bool
+}
+
+// NewTinifyKeyPool 使用给定的 Key 列表创建一个 Key 池
+func NewTinifyKeyPool(keys []string) *TinifyKeyPool {
+	return &TinifyKeyPool{
+		keys:    keys,
+		usage:   make(map[string]int),
+		invalid: make(map[string]bool),
+	}
+}
+
+// LoadTinifyKeyPool 从形如 keys.txt 的文件中加载 Key 列表，每行一个 Key，支持 # 开头的注释
+func LoadTinifyKeyPool(path string) (*TinifyKeyPool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var keys []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key := strings.TrimSpace(scanner.Text())
+		if key == "" || strings.HasPrefix(key, "#") {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewTinifyKeyPool(keys), nil
+}
+
+// Len 返回池中 Key 的数量
+func (p *TinifyKeyPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.keys)
+}
+
+// Acquire 轮询返回下一个未失效且未超额的 Key；全部不可用时返回错误
+func (p *TinifyKeyPool) Acquire() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i := 0; i < len(p.keys); i++ {
+		key := p.keys[p.next%len(p.keys)]
+		p.next++
+		if p.invalid[key] || p.usage[key] >= tinifyMonthlyFreeQuota {
+			continue
+		}
+		return key, nil
+	}
+	return "", fmt.Errorf("没有可用的 tinify key：全部已失效或已达到月度额度")
+}
+
+// RecordUsage 根据 Compression-Count 响应头更新某个 Key 的已用次数
+func (p *TinifyKeyPool) RecordUsage(key string, count int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.usage[key] = count
+}
+
+// MarkInvalid 将 Key 标记为失效（401）或已耗尽配额（429），之后的 Acquire 会跳过它
+func (p *TinifyKeyPool) MarkInvalid(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.invalid[key] = true
+}
+
+// Usage 返回所有 Key 当前用量的快照，用于最终报告
+func (p *TinifyKeyPool) Usage() map[string]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	snapshot := make(map[string]int, len(p.usage))
+	for k, v := range p.usage {
+		snapshot[k] = v
+	}
+	return snapshot
+}
@@ -0,0 +1,83 @@
+package main
+
+import "testing"
+
+func TestTinifyKeyPoolAcquireRotation(t *testing.T) {
+	cases := []struct {
+		name    string
+		keys    []string
+		setup   func(p *TinifyKeyPool)
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "轮询所有 key",
+			keys: []string{"a", "b", "c"},
+			want: []string{"a", "b", "c", "a"},
+		},
+		{
+			name: "跳过已失效的 key",
+			keys: []string{"a", "b", "c"},
+			setup: func(p *TinifyKeyPool) {
+				p.MarkInvalid("b")
+			},
+			want: []string{"a", "c", "a", "c"},
+		},
+		{
+			name: "跳过已达到月度额度的 key",
+			keys: []string{"a", "b"},
+			setup: func(p *TinifyKeyPool) {
+				p.RecordUsage("a", tinifyMonthlyFreeQuota)
+			},
+			want: []string{"b", "b"},
+		},
+		{
+			name: "全部不可用时返回错误",
+			keys: []string{"a", "b"},
+			setup: func(p *TinifyKeyPool) {
+				p.MarkInvalid("a")
+				p.MarkInvalid("b")
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := NewTinifyKeyPool(tc.keys)
+			if tc.setup != nil {
+				tc.setup(pool)
+			}
+
+			for i, want := range tc.want {
+				got, err := pool.Acquire()
+				if err != nil {
+					t.Fatalf("Acquire() #%d returned error: %v", i, err)
+				}
+				if got != want {
+					t.Errorf("Acquire() #%d = %q, want %q", i, got, want)
+				}
+			}
+
+			if tc.wantErr {
+				if _, err := pool.Acquire(); err == nil {
+					t.Error("Acquire() expected error when all keys are unavailable, got nil")
+				}
+			}
+		})
+	}
+}
+
+func TestTinifyKeyPoolRecordAndMarkInvalid(t *testing.T) {
+	pool := NewTinifyKeyPool([]string{"a"})
+
+	pool.RecordUsage("a", 10)
+	if got := pool.Usage()["a"]; got != 10 {
+		t.Fatalf("Usage()[\"a\"] = %d, want 10", got)
+	}
+
+	pool.MarkInvalid("a")
+	if _, err := pool.Acquire(); err == nil {
+		t.Error("Acquire() expected error after key was marked invalid, got nil")
+	}
+}